@@ -0,0 +1,123 @@
+package zipf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// FitResult is the outcome of fitting a Zipf distribution to a ranked
+// frequency list.
+type FitResult struct {
+	// S is the estimated Zipf exponent: f(r) ∝ 1/r^S.
+	S float64
+	// KS is the Kolmogorov–Smirnov distance between the empirical CDF
+	// of ranks and the fitted Zipf CDF.
+	KS float64
+	// N is the number of ranks the fit was computed over.
+	N int
+}
+
+// Fit estimates the Zipf exponent for the distribution produced by the
+// last call to Report, using maximum likelihood: s is the root of
+//
+//	sum_r f_r*ln(r) = -total * H'_{N,s} / H_{N,s}
+//
+// found by Newton iteration, where H_{N,s} = sum_{i=1}^N i^-s is the
+// generalized harmonic number, H'_{N,s} = -sum_{i=1}^N i^-s*ln(i) is its
+// derivative with respect to s, and total is the sum of all counts. It
+// also reports the Kolmogorov–Smirnov distance between the empirical
+// rank CDF and the CDF of the fitted Zipf distribution.
+func (z *Zipf) Fit() (FitResult, error) {
+	z.RLock()
+	src := z.collection
+	z.RUnlock()
+	if len(src) == 0 {
+		return FitResult{}, errors.New("zipf: no ranked terms to fit, call Report first")
+	}
+
+	ranked := make([]Term, len(src))
+	copy(ranked, src)
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].Count > ranked[j].Count })
+
+	n := len(ranked)
+	var total, sumLogR float64
+	for i, t := range ranked {
+		r := float64(i + 1)
+		total += float64(t.Count)
+		sumLogR += float64(t.Count) * math.Log(r)
+	}
+
+	s := fitZipfExponent(n, total, sumLogR)
+	ks := zipfKS(ranked, total, s)
+
+	return FitResult{S: s, KS: ks, N: n}, nil
+}
+
+// fitZipfExponent solves for s by Newton iteration starting at s=1. The
+// MLE equation is monotonic in s (its derivative is a variance term, so
+// never negative), which keeps the iteration well behaved.
+func fitZipfExponent(n int, total, sumLogR float64) float64 {
+	s := 1.0
+	const maxIter = 100
+	const tol = 1e-10
+
+	for i := 0; i < maxIter; i++ {
+		a, b, c := harmonicSums(n, s)
+		g := sumLogR - total*b/a
+		gp := total * (a*c - b*b) / (a * a)
+		if gp == 0 {
+			break
+		}
+		next := s - g/gp
+		if next <= 0 {
+			next = s / 2
+		}
+		if math.Abs(next-s) < tol {
+			return next
+		}
+		s = next
+	}
+	return s
+}
+
+// harmonicSums returns A = H_{N,s} = sum i^-s, and the first and second
+// derivative helpers B = sum i^-s*ln(i), C = sum i^-s*ln(i)^2 needed by
+// Newton's method.
+func harmonicSums(n int, s float64) (a, b, c float64) {
+	for i := 1; i <= n; i++ {
+		x := float64(i)
+		p := math.Pow(x, -s)
+		l := math.Log(x)
+		a += p
+		b += p * l
+		c += p * l * l
+	}
+	return a, b, c
+}
+
+// zipfKS returns the Kolmogorov–Smirnov distance between the empirical
+// CDF of ranked (by cumulative count share) and the CDF of a Zipf
+// distribution with exponent s over the same N ranks.
+func zipfKS(ranked []Term, total, s float64) float64 {
+	norm, _, _ := harmonicSums(len(ranked), s)
+
+	var empCum, fitCum, ks float64
+	for i, t := range ranked {
+		empCum += float64(t.Count)
+		fitCum += math.Pow(float64(i+1), -s)
+
+		d := math.Abs(empCum/total - fitCum/norm)
+		if d > ks {
+			ks = d
+		}
+	}
+	return ks
+}
+
+// String renders a FitResult the way Run prints it alongside the
+// ranked list.
+func (f FitResult) String() string {
+	return fmt.Sprintf("zipf exponent s=%.4f ks=%.4f n=%d", f.S, f.KS, f.N)
+}
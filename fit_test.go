@@ -0,0 +1,50 @@
+package zipf
+
+import (
+	"math"
+	"testing"
+)
+
+// TestFitRecoversKnownExponent builds a ranked collection that exactly
+// follows f(r) = round(scale / r^s) for a known s, and checks that Fit
+// recovers s with a tight Kolmogorov-Smirnov distance.
+func TestFitRecoversKnownExponent(t *testing.T) {
+	const (
+		s     = 1.3
+		n     = 200
+		scale = 100000
+	)
+
+	var collection []Term
+	for r := 1; r <= n; r++ {
+		count := int64(math.Round(scale / math.Pow(float64(r), s)))
+		if count < 1 {
+			count = 1
+		}
+		collection = append(collection, Term{Word: "w", Count: count})
+	}
+
+	z := newTestZipf(t)
+	z.collection = collection
+
+	result, err := z.Fit()
+	if err != nil {
+		t.Fatalf("Fit: %v", err)
+	}
+	if result.N != n {
+		t.Fatalf("N = %d, want %d", result.N, n)
+	}
+	if math.Abs(result.S-s) > 0.01 {
+		t.Fatalf("S = %.4f, want within 0.01 of %.4f", result.S, s)
+	}
+	if result.KS > 0.05 {
+		t.Fatalf("KS = %.4f, want <= 0.05 for an exact Zipf distribution", result.KS)
+	}
+}
+
+func TestFitWithoutReportErrors(t *testing.T) {
+	z := newTestZipf(t)
+	if _, err := z.Fit(); err == nil {
+		t.Fatal("Fit on an empty collection should error")
+	}
+}
@@ -0,0 +1,423 @@
+package zipf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// On-disk index format: a magic/version header followed by the root
+// path that was indexed, then per-file records sorted by file path:
+//
+//	magic   [4]byte  "ZPF1"
+//	version byte
+//	pathLen uvarint
+//	path    []byte
+//	records ...
+//	  nameLen  uvarint
+//	  name     []byte
+//	  termLen  uvarint
+//	  terms ...
+//	    count    varint
+//	    wordLen  uint16
+//	    word     []byte
+//
+// The index stores a per-file breakdown rather than a flat word->count
+// table. That is what lets Apply correctly un-count a file across
+// process restarts: Load restores z.files as well as the aggregate
+// z.words it sums from them, so a later "-"/"M" line for a file that
+// was last tokenized in a previous run can still be forgotten.
+const (
+	indexMagic   = "ZPF1"
+	indexVersion = 2
+)
+
+// fileRec pairs a file name with its term counts. Save and Merge build
+// a sorted slice of these under z's lock, then do the actual file I/O
+// lock-free, the same way Save used to snapshot z.words into a []Term.
+type fileRec struct {
+	name  string
+	terms map[string]int64
+}
+
+// snapshotFiles returns z.files as a slice sorted by name, alongside
+// the root path it was built from.
+func (z *Zipf) snapshotFiles() (string, []fileRec) {
+	z.RLock()
+	defer z.RUnlock()
+	recs := make([]fileRec, 0, len(z.files))
+	for name, terms := range z.files {
+		recs = append(recs, fileRec{name: name, terms: terms})
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].name < recs[j].name })
+	return z.path, recs
+}
+
+// Save persists z's current per-file term tables to path, overwriting
+// whatever index was already there: the file on disk always mirrors
+// z's in-memory state exactly, so Load(path) after Save(path) round-trips
+// without drift. To fold z's current counts into an existing on-disk
+// index instead of replacing it outright, use Merge.
+func (z *Zipf) Save(path string) error {
+	root, recs := z.snapshotFiles()
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	err = func() error {
+		if err := writeIndexHeader(bw, root); err != nil {
+			return err
+		}
+		if err := writeFileRecords(bw, recs); err != nil {
+			return err
+		}
+		return bw.Flush()
+	}()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Merge folds z's current per-file term tables into the index already
+// at path, without loading the existing index into memory: the
+// on-disk records and z's in-memory ones are both sorted by file name,
+// so a single streaming merge pass produces the combined, still-sorted
+// result. Where a file name appears on both sides, z's version wins,
+// since it reflects a more recent tokenization of that file; where
+// path has no previous index, Merge behaves like Save.
+func (z *Zipf) Merge(path string) error {
+	root, recs := z.snapshotFiles()
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	err = func() error {
+		if err := writeIndexHeader(bw, root); err != nil {
+			return err
+		}
+		old, err := os.Open(path)
+		if err != nil {
+			if err := writeFileRecords(bw, recs); err != nil {
+				return err
+			}
+			return bw.Flush()
+		}
+		defer old.Close()
+		br := bufio.NewReader(old)
+		if _, err := readIndexHeader(br); err != nil {
+			return err
+		}
+		if err := mergeFileRecords(bw, br, recs); err != nil {
+			return err
+		}
+		return bw.Flush()
+	}()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load replaces z's word-count table and per-file breakdown with the
+// ones stored at path.
+func (z *Zipf) Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	root, err := readIndexHeader(br)
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string]map[string]int64)
+	words := make(map[string]int64)
+	for {
+		name, terms, err := readFileRecord(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		files[name] = terms
+		for w, c := range terms {
+			words[w] += c
+		}
+	}
+
+	z.Lock()
+	z.path = root
+	z.words = words
+	z.files = files
+	z.Unlock()
+	return nil
+}
+
+// Apply consumes a line-oriented change stream, one change per line in
+// the form "+ path", "- path" or "M path", and updates z's counts by
+// tokenizing the added/modified files through z.src and adding their
+// words, and subtracting the words of removed/modified files.
+//
+// Subtracting relies on a file's previous token counts being recorded
+// in z.files, either by an earlier Walk/Apply in this process or by a
+// prior Load: since the on-disk index keeps a per-file breakdown, not
+// just aggregate counts, a "-" or "M" line for a file that was last
+// indexed in a different process still un-counts correctly as long as
+// that index was Loaded first.
+func (z *Zipf) Apply(r io.Reader) error {
+	scan := bufio.NewScanner(r)
+	for scan.Scan() {
+		line := scan.Text()
+		if len(line) < 3 || line[1] != ' ' {
+			continue
+		}
+		op, name := line[0], line[2:]
+
+		switch op {
+		case '-':
+			z.forget(name)
+		case '+', 'M':
+			if op == 'M' {
+				z.forget(name)
+			}
+			terms, err := z.tokenize(name)
+			if err != nil {
+				return err
+			}
+			z.Lock()
+			for w, c := range terms {
+				z.words[w] += c
+			}
+			if z.files == nil {
+				z.files = make(map[string]map[string]int64)
+			}
+			z.files[name] = terms
+			z.Unlock()
+		default:
+			return fmt.Errorf("index: unknown change op %q", op)
+		}
+	}
+	return scan.Err()
+}
+
+// forget subtracts the previously recorded tokens of name, if any, from
+// z.words and removes its entry from z.files.
+func (z *Zipf) forget(name string) {
+	z.Lock()
+	defer z.Unlock()
+	old, ok := z.files[name]
+	if !ok {
+		return
+	}
+	for w, c := range old {
+		z.words[w] -= c
+		if z.words[w] <= 0 {
+			delete(z.words, w)
+		}
+	}
+	delete(z.files, name)
+}
+
+func writeIndexHeader(w io.Writer, root string) error {
+	if _, err := io.WriteString(w, indexMagic); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte{indexVersion}); err != nil {
+		return err
+	}
+	return writeString(w, root)
+}
+
+func readIndexHeader(r io.Reader) (string, error) {
+	magic := make([]byte, len(indexMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return "", err
+	}
+	if string(magic) != indexMagic {
+		return "", fmt.Errorf("index: bad magic %q", magic)
+	}
+	version := make([]byte, 1)
+	if _, err := io.ReadFull(r, version); err != nil {
+		return "", err
+	}
+	if version[0] != indexVersion {
+		return "", fmt.Errorf("index: unsupported version %d", version[0])
+	}
+	return readString(r)
+}
+
+func writeString(w io.Writer, s string) error {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(len(s)))
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+func readString(r io.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r.(io.ByteReader))
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func writeRecord(w io.Writer, word string, count int64) error {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], count)
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+	var lenBuf [2]byte
+	binary.BigEndian.PutUint16(lenBuf[:], uint16(len(word)))
+	if _, err := w.Write(lenBuf[:]); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, word)
+	return err
+}
+
+func readRecord(r *bufio.Reader) (string, int64, error) {
+	count, err := binary.ReadVarint(r)
+	if err != nil {
+		return "", 0, err
+	}
+	var lenBuf [2]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		return "", 0, err
+	}
+	wordLen := binary.BigEndian.Uint16(lenBuf[:])
+	word := make([]byte, wordLen)
+	if _, err := io.ReadFull(r, word); err != nil {
+		return "", 0, err
+	}
+	return string(word), count, nil
+}
+
+// writeFileRecord writes name's term table as one file record, laid
+// out as described in the format comment above. Words within the
+// record are written in sorted order so two saves of the same
+// in-memory state produce byte-identical output.
+func writeFileRecord(w io.Writer, name string, terms map[string]int64) error {
+	if err := writeString(w, name); err != nil {
+		return err
+	}
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(len(terms)))
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+	words := make([]string, 0, len(terms))
+	for word := range terms {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	for _, word := range words {
+		if err := writeRecord(w, word, terms[word]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFileRecords(w io.Writer, recs []fileRec) error {
+	for _, rec := range recs {
+		if err := writeFileRecord(w, rec.name, rec.terms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFileRecord reads one file record written by writeFileRecord. It
+// returns io.EOF, unwrapped, when r is exhausted between records.
+func readFileRecord(r *bufio.Reader) (string, map[string]int64, error) {
+	name, err := readString(r)
+	if err != nil {
+		return "", nil, err
+	}
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", nil, err
+	}
+	terms := make(map[string]int64, n)
+	for i := uint64(0); i < n; i++ {
+		word, count, err := readRecord(r)
+		if err != nil {
+			return "", nil, err
+		}
+		terms[word] = count
+	}
+	return name, terms, nil
+}
+
+// mergeFileRecords streams the sorted file records in old and the
+// sorted slice in new, writing new's record whenever a file name
+// appears on both sides (new always wins over stale on-disk data), and
+// writes the merged, still-sorted result to w.
+func mergeFileRecords(w io.Writer, old *bufio.Reader, new []fileRec) error {
+	oldName, oldTerms, oldErr := readFileRecord(old)
+	i := 0
+	for oldErr == nil || i < len(new) {
+		switch {
+		case oldErr != nil:
+			if err := writeFileRecord(w, new[i].name, new[i].terms); err != nil {
+				return err
+			}
+			i++
+		case i >= len(new):
+			if err := writeFileRecord(w, oldName, oldTerms); err != nil {
+				return err
+			}
+			oldName, oldTerms, oldErr = readFileRecord(old)
+		case oldName == new[i].name:
+			if err := writeFileRecord(w, new[i].name, new[i].terms); err != nil {
+				return err
+			}
+			oldName, oldTerms, oldErr = readFileRecord(old)
+			i++
+		case oldName < new[i].name:
+			if err := writeFileRecord(w, oldName, oldTerms); err != nil {
+				return err
+			}
+			oldName, oldTerms, oldErr = readFileRecord(old)
+		default:
+			if err := writeFileRecord(w, new[i].name, new[i].terms); err != nil {
+				return err
+			}
+			i++
+		}
+	}
+	if oldErr != nil && oldErr != io.EOF {
+		return oldErr
+	}
+	return nil
+}
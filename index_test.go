@@ -0,0 +1,155 @@
+package zipf
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestZipf returns a Zipf whose source is the testdata directory,
+// which it never reads, suitable for tests that only exercise
+// Save/Load/Apply against z.words/z.files directly.
+func newTestZipf(t *testing.T) *Zipf {
+	t.Helper()
+	z, err := New("testdata", 0, false, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return z
+}
+
+func termTotals(words map[string]int64) map[string]int64 {
+	out := make(map[string]int64, len(words))
+	for w, c := range words {
+		out[w] = c
+	}
+	return out
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	z := newTestZipf(t)
+	z.files = map[string]map[string]int64{
+		"a.txt": {"alpha": 1, "beta": 1},
+		"b.txt": {"beta": 1, "gamma": 1},
+	}
+	for _, terms := range z.files {
+		for w, c := range terms {
+			z.words[w] += c
+		}
+	}
+
+	want := termTotals(z.words)
+
+	path := filepath.Join(t.TempDir(), "index")
+	if err := z.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded := newTestZipf(t)
+	if err := loaded.Load(path); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := termTotals(loaded.words); !mapsEqual(got, want) {
+		t.Fatalf("words after Load = %v, want %v", got, want)
+	}
+	if len(loaded.files) != len(z.files) {
+		t.Fatalf("files after Load = %d entries, want %d", len(loaded.files), len(z.files))
+	}
+
+	// A second Save/Load cycle must be a no-op: Save always overwrites,
+	// so counts should not drift on repeated round trips.
+	if err := loaded.Save(path); err != nil {
+		t.Fatalf("second Save: %v", err)
+	}
+	reloaded := newTestZipf(t)
+	if err := reloaded.Load(path); err != nil {
+		t.Fatalf("second Load: %v", err)
+	}
+	if got := termTotals(reloaded.words); !mapsEqual(got, want) {
+		t.Fatalf("words after Save.Load.Save.Load = %v, want %v (counts drifted)", got, want)
+	}
+}
+
+func TestMergeKeepsNewOnConflict(t *testing.T) {
+	z := newTestZipf(t)
+	z.files = map[string]map[string]int64{
+		"a.txt": {"alpha": 1},
+		"b.txt": {"beta": 2},
+	}
+	z.words = map[string]int64{"alpha": 1, "beta": 2}
+
+	path := filepath.Join(t.TempDir(), "index")
+	if err := z.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// z re-tokenizes a.txt with a different count and gains a new file;
+	// b.txt is untouched in this process.
+	z2 := newTestZipf(t)
+	z2.files = map[string]map[string]int64{
+		"a.txt": {"alpha": 5},
+		"c.txt": {"gamma": 1},
+	}
+	z2.words = map[string]int64{"alpha": 5, "gamma": 1}
+
+	if err := z2.Merge(path); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+
+	merged := newTestZipf(t)
+	if err := merged.Load(path); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := map[string]int64{"alpha": 5, "beta": 2, "gamma": 1}
+	if got := termTotals(merged.words); !mapsEqual(got, want) {
+		t.Fatalf("words after Merge = %v, want %v", got, want)
+	}
+	if len(merged.files) != 3 {
+		t.Fatalf("files after Merge = %d entries, want 3", len(merged.files))
+	}
+}
+
+func TestApplyAfterLoadForgetsRemovedFile(t *testing.T) {
+	z := newTestZipf(t)
+	z.files = map[string]map[string]int64{
+		"a.txt": {"alpha": 1, "shared": 2},
+		"b.txt": {"beta": 1, "shared": 3},
+	}
+	z.words = map[string]int64{"alpha": 1, "beta": 1, "shared": 5}
+
+	path := filepath.Join(t.TempDir(), "index")
+	if err := z.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// Simulate a fresh process that only has the on-disk index, then
+	// applies a diff stream removing a.txt.
+	fresh := newTestZipf(t)
+	if err := fresh.Load(path); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := fresh.Apply(strings.NewReader("- a.txt\n")); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	want := map[string]int64{"beta": 1, "shared": 3}
+	if got := termTotals(fresh.words); !mapsEqual(got, want) {
+		t.Fatalf("words after Load+Apply(-a.txt) = %v, want %v", got, want)
+	}
+	if _, ok := fresh.files["a.txt"]; ok {
+		t.Fatal("a.txt still present in files after removal")
+	}
+}
+
+func mapsEqual(a, b map[string]int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
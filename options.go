@@ -0,0 +1,36 @@
+package zipf
+
+// Option configures optional knobs on a Zipf created via New.
+type Option func(*Zipf)
+
+// WithWorkers sets the number of goroutines used to ingest files. The
+// default is 1 (sequential).
+func WithWorkers(n int) Option {
+	return func(z *Zipf) { z.workers = n }
+}
+
+// WithExternalSort enables the external-sort report path: once the
+// vocabulary grows past the spill threshold, Report spills sorted runs
+// to temp files and k-way merges them instead of ranking everything
+// in memory.
+func WithExternalSort(enabled bool) Option {
+	return func(z *Zipf) { z.externalSort = enabled }
+}
+
+// WithSpillThreshold sets how many unique words Report keeps in memory
+// before it starts spilling sorted runs to disk. Only takes effect when
+// WithExternalSort is enabled.
+func WithSpillThreshold(n int) Option {
+	return func(z *Zipf) { z.spillThreshold = n }
+}
+
+// WithTempDir sets the directory used for spilled run files. Defaults
+// to os.TempDir().
+func WithTempDir(dir string) Option {
+	return func(z *Zipf) { z.tmpDir = dir }
+}
+
+// WithFit makes Run print a Fit result alongside the ranked list.
+func WithFit(enabled bool) Option {
+	return func(z *Zipf) { z.fit = enabled }
+}
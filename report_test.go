@@ -0,0 +1,56 @@
+package zipf
+
+import (
+	"fmt"
+	"testing"
+)
+
+// rankedSet normalizes a ranked []Term for comparison: Report only
+// guarantees ascending order by count, so words tied on count may come
+// out in either order between the in-memory and external paths.
+func rankedSet(t *testing.T) func([]Term) map[string]int64 {
+	t.Helper()
+	return func(terms []Term) map[string]int64 {
+		out := make(map[string]int64, len(terms))
+		for _, term := range terms {
+			out[term.Word] = term.Count
+		}
+		return out
+	}
+}
+
+func TestReportInMemoryAndExternalAgree(t *testing.T) {
+	z := newTestZipf(t)
+	z.limit = 5
+	z.words = make(map[string]int64)
+	for i := 0; i < 50; i++ {
+		z.words[fmt.Sprintf("word%02d", i)] = int64(i)
+	}
+
+	toSet := rankedSet(t)
+
+	inMemory, err := z.reportInMemory()
+	if err != nil {
+		t.Fatalf("reportInMemory: %v", err)
+	}
+
+	z.tmpDir = t.TempDir()
+	z.spillThreshold = 7
+	external, err := z.reportExternal()
+	if err != nil {
+		t.Fatalf("reportExternal: %v", err)
+	}
+
+	if len(inMemory) != z.limit || len(external) != z.limit {
+		t.Fatalf("got %d in-memory / %d external terms, want %d", len(inMemory), len(external), z.limit)
+	}
+	if got, want := toSet(inMemory), toSet(external); !mapsEqual(got, want) {
+		t.Fatalf("reportInMemory = %v, reportExternal = %v", got, want)
+	}
+
+	for i := 1; i < len(inMemory); i++ {
+		if inMemory[i].Count < inMemory[i-1].Count {
+			t.Fatalf("reportInMemory not ascending: %v", inMemory)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package source abstracts where Zipf reads its files from, so the
+// analyser can walk a local directory, a local zip archive or a zip
+// archive served over HTTP without caring which one it got.
+package source
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Source is anything Zipf can walk and read files from.
+type Source = fs.FS
+
+// Open picks a Source implementation based on the scheme/extension of
+// path: an "http://" or "https://" URL is treated as a remote zip, a
+// path ending in ".zip" is opened as a local zip archive, anything else
+// is treated as a local directory.
+func Open(path string) (Source, error) {
+	switch {
+	case strings.HasPrefix(path, "http://"), strings.HasPrefix(path, "https://"):
+		return OpenHTTPZip(path)
+	case strings.HasSuffix(path, ".zip"):
+		return OpenZip(path)
+	default:
+		return OpenDir(path)
+	}
+}
+
+// OpenDir returns a Source backed by a local directory.
+func OpenDir(dir string) (Source, error) {
+	return os.DirFS(dir), nil
+}
+
+// OpenZip returns a Source backed by a local zip archive. The returned
+// *zip.ReadCloser holds the archive's file handle open; it also
+// implements io.Closer, and Zipf.Close releases it once Zipf is done
+// with the source.
+func OpenZip(path string) (Source, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// OpenHTTPZip returns a Source backed by a zip archive served over HTTP.
+// It fetches the archive's size with a HEAD request and then reads the
+// central directory and individual entries via range requests, so a
+// single file can be indexed without downloading the whole archive.
+func OpenHTTPZip(url string) (Source, error) {
+	cli := http.DefaultClient
+	size, err := contentLength(cli, url)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(&httpReaderAt{url: url, cli: cli}, size)
+	if err != nil {
+		return nil, err
+	}
+	return zr, nil
+}
+
+func contentLength(cli *http.Client, url string) (int64, error) {
+	resp, err := cli.Head(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("source: unexpected status %d for HEAD %s", resp.StatusCode, url)
+	}
+	return strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+}
+
+// httpReaderAt implements io.ReaderAt over an HTTP resource using range
+// requests, so archive/zip only pulls the bytes it actually needs.
+type httpReaderAt struct {
+	url string
+	cli *http.Client
+}
+
+func (h *httpReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	req, err := http.NewRequest(http.MethodGet, h.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+
+	resp, err := h.cli.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, fmt.Errorf("source: server does not support range requests (status %d)", resp.StatusCode)
+	}
+	return io.ReadFull(resp.Body, p)
+}
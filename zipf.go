@@ -5,142 +5,245 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
-	"sort"
+	"io/fs"
 	"sync"
+
+	"github.com/jimmy-go/zipfs/source"
 )
 
 // Zipf type.
 type Zipf struct {
 	path       string
+	src        source.Source
 	limit      int
 	out        io.Writer
 	symbols    bool
 	words      map[string]int64
 	counts     map[int64]string
+	files      map[string]map[string]int64
 	collection []Term
+
+	workers        int
+	externalSort   bool
+	spillThreshold int
+	tmpDir         string
+	fit            bool
 	sync.RWMutex
 }
 
-// New returns a Zipf analiser.
-func New(dir string, limit int, symbols bool, output io.Writer) (*Zipf, error) {
-	if dir == "" {
-		return nil, errors.New("empty dir")
+// New returns a Zipf analiser. path may be a local directory, a local
+// .zip archive or an http(s):// URL pointing at a .zip archive; the
+// right source.Source is picked from its scheme/extension.
+func New(path string, limit int, symbols bool, output io.Writer, opts ...Option) (*Zipf, error) {
+	if path == "" {
+		return nil, errors.New("empty path")
+	}
+	src, err := source.Open(path)
+	if err != nil {
+		return nil, err
 	}
 	z := &Zipf{
-		path:    dir,
+		path:    path,
+		src:     src,
 		limit:   limit,
 		out:     output,
 		symbols: symbols,
 		words:   make(map[string]int64),
 		counts:  make(map[int64]string),
+		files:   make(map[string]map[string]int64),
+		workers: 1,
+	}
+	for _, opt := range opts {
+		opt(z)
 	}
 	return z, nil
 }
 
-// Run executes the file path walk and report.
+// Run executes the file path walk and report, and, if z.fit was set via
+// WithFit, also fits a Zipf distribution to the result and prints it.
 func (z *Zipf) Run() error {
-	if err := z.Walk(z.path); err != nil {
+	defer z.Close()
+	if err := z.Walk(); err != nil {
 		return err
 	}
 	if err := z.Report(); err != nil {
 		return err
 	}
+	if z.fit {
+		result, err := z.Fit()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(z.out, result)
+	}
 	return nil
 }
 
-// Walk read all files in dir and populate the word's count.
-func (z *Zipf) Walk(dir string) error {
-	err := filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
-		// skip directories
-		if info != nil && info.IsDir() {
-			return nil
-		}
+// Close releases any resources held by z's underlying source.Source,
+// such as an open zip archive's file handle. It is safe to call even
+// when src doesn't need closing, e.g. a directory or an HTTP zip.
+func (z *Zipf) Close() error {
+	if c, ok := z.src.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
 
-		// read file
-		lines, err := readLines(name)
-		if err != nil {
-			return err
-		}
+// fileTerms pairs a file name with the term counts tokenize found in it.
+type fileTerms struct {
+	name  string
+	terms map[string]int64
+}
 
-		for i := range lines {
-			line := lines[i]
-			// skip empty lines
-			if len(line) < 1 {
-				continue
-			}
+// shard is one worker's private tally, merged into z once the worker
+// has drained its share of paths.
+type shard struct {
+	words map[string]int64
+	files []fileTerms
+	err   error
+}
 
-			// Words
-			words, err := SplitWord(line)
-			if err != nil {
-				continue
-			}
-			for _, w := range words {
-				if err := z.Add(w); err != nil {
-					return err
-				}
-			}
+// Walk reads all files reachable from z.src and populates the word's
+// count. z.workers goroutines tokenize files concurrently, each
+// accumulating its own local word counts; the shards are merged under
+// z's mutex only once per worker, not once per file, to keep lock
+// contention off the hot path.
+func (z *Zipf) Walk() error {
+	workers := z.workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	paths := make(chan string, workers*4)
+	shards := make(chan shard, workers)
 
-			if z.symbols {
-				// Symbols
-				ss, err := SplitSymbol(line)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			s := shard{words: make(map[string]int64)}
+			for name := range paths {
+				terms, err := z.tokenize(name)
 				if err != nil {
+					if s.err == nil {
+						s.err = err
+					}
 					continue
 				}
-				for _, w := range ss {
-					if err := z.Add(w); err != nil {
-						return err
-					}
+				for w, c := range terms {
+					s.words[w] += c
 				}
+				s.files = append(s.files, fileTerms{name: name, terms: terms})
 			}
-		}
-		return nil
-	})
-	return err
-}
+			shards <- s
+		}()
+	}
 
-// Add queue words to the map of words and sums 1 to existent words.
-func (z *Zipf) Add(s string) error {
-	z.RLock()
-	defer z.RUnlock()
+	walkDone := make(chan error, 1)
+	go func() {
+		defer close(paths)
+		walkDone <- fs.WalkDir(z.src, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// skip directories
+			if d.IsDir() {
+				return nil
+			}
+			paths <- name
+			return nil
+		})
+	}()
 
-	if s == "" {
-		return errors.New("empty word")
+	go func() {
+		wg.Wait()
+		close(shards)
+	}()
+
+	var firstErr error
+	for s := range shards {
+		if s.err != nil && firstErr == nil {
+			firstErr = s.err
+		}
+		z.Lock()
+		for w, c := range s.words {
+			z.words[w] += c
+		}
+		for _, ft := range s.files {
+			z.files[ft.name] = ft.terms
+		}
+		z.Unlock()
 	}
-	count, ok := z.words[s]
-	if !ok {
-		z.words[s] = 1
+	if firstErr != nil {
+		return firstErr
 	}
-	z.words[s] = count + 1
-	return nil
+	return <-walkDone
 }
 
-// Report report words count without order.
-func (z *Zipf) Report() error {
-	z.RLock()
-	defer z.RUnlock()
+// tokenize reads name through z.src and returns the word (and, if
+// z.symbols is set, symbol) counts it contains. It holds no lock: the
+// caller is responsible for merging the result into shared state.
+func (z *Zipf) tokenize(name string) (map[string]int64, error) {
+	lines, err := readLines(z.src, name)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := make(map[string]int64)
+	for i := range lines {
+		line := lines[i]
+		// skip empty lines
+		if len(line) < 1 {
+			continue
+		}
 
-	var i int
-	for k, c := range z.words {
-		i++
-		if i > z.limit {
+		// Words
+		words, err := SplitWord(line)
+		if err != nil {
 			continue
 		}
-		z.collection = append(z.collection, Term{Word: k, Count: c})
+		for _, w := range words {
+			if w == "" {
+				continue
+			}
+			terms[w]++
+		}
+
+		if z.symbols {
+			// Symbols
+			ss, err := SplitSymbol(line)
+			if err != nil {
+				continue
+			}
+			for _, w := range ss {
+				if w == "" {
+					continue
+				}
+				terms[w]++
+			}
+		}
 	}
+	return terms, nil
+}
 
-	sort.Sort(ByCountAsc(z.collection))
+// Add sums 1 to the count for s, creating the entry if it is new. It
+// takes the write lock, since it mutates z.words: RLock only excludes
+// other writers from the *lock*, not from the map itself, so concurrent
+// Add calls under RLock were a straight data race.
+func (z *Zipf) Add(s string) error {
+	z.Lock()
+	defer z.Unlock()
 
-	for i := range z.collection {
-		x := z.collection[i]
-		fmt.Fprintf(z.out, "%s %d\n", x.Word, x.Count)
+	if s == "" {
+		return errors.New("empty word")
 	}
+	z.words[s]++
 	return nil
 }
 
-func readLines(path string) ([]string, error) {
-	f, err := os.Open(path)
+func readLines(src source.Source, name string) ([]string, error) {
+	f, err := src.Open(name)
 	if err != nil {
 		return nil, err
 	}
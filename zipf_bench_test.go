@@ -0,0 +1,47 @@
+package zipf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupCorpus writes n files of repeated text into a temp dir and
+// returns its path.
+func setupCorpus(b *testing.B, n int) string {
+	b.Helper()
+	dir := b.TempDir()
+	line := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog\n"), 500)
+	for i := 0; i < n; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("file%d.txt", i))
+		if err := os.WriteFile(name, line, 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// BenchmarkWalk shows Walk's scaling as z.workers grows: with
+// independent per-file tokenization and a merge that locks once per
+// worker rather than once per file, wall time should drop close to
+// linearly as workers increase, up to the point file I/O dominates.
+func BenchmarkWalk(b *testing.B) {
+	dir := setupCorpus(b, 200)
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				z, err := New(dir, 10, false, io.Discard, WithWorkers(workers))
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := z.Walk(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}